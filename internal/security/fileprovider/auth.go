@@ -0,0 +1,175 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edgexfoundry/edgex-go/internal/security/common"
+	"github.com/edgexfoundry/edgex-go/internal/security/fileprovider/config"
+	"github.com/edgexfoundry/edgex-go/internal/security/secretstore"
+)
+
+// Supported values for a service's AuthMethod configuration. userpass remains the
+// default so existing TokenConfFile entries keep working unchanged.
+const (
+	authMethodUserPass = "userpass"
+	authMethodAppRole  = "approle"
+	authMethodJWT      = "jwt"
+)
+
+// approleCredentialsFilename is written alongside the token file for approle
+// services, holding the role_id/secret_id pair needed to log in again.
+const approleCredentialsFilename = "approle.json"
+
+// serviceRelogin knows how to log a service back in using whatever credentials
+// its auth method cached at bootstrap time. p.credentials holds one of these per
+// service so reloginAndWrite can recover a non-renewable token regardless of
+// which auth method originally bootstrapped it.
+type serviceRelogin interface {
+	relogin(p *fileTokenProvider, privilegedToken string) (interface{}, error)
+}
+
+// userPassRelogin re-authenticates via Vault's userpass auth method.
+type userPassRelogin struct {
+	username string
+	password string
+}
+
+func (c userPassRelogin) relogin(p *fileTokenProvider, privilegedToken string) (interface{}, error) {
+	return p.secretStoreClient.InternalServiceLogin(privilegedToken, p.tokenConfig.UserPassMountPoint, c.username, c.password)
+}
+
+// appRoleRelogin re-authenticates via Vault's approle auth method using the
+// role_id/secret_id pair generated at bootstrap time.
+type appRoleRelogin struct {
+	roleID   string
+	secretID string
+}
+
+func (c appRoleRelogin) relogin(p *fileTokenProvider, _ string) (interface{}, error) {
+	return newVaultAuthClient(p.secretStoreConfig).appRoleLogin(c.roleID, c.secretID)
+}
+
+// bootstrapUserPass creates a Vault userpass user bound to servicePolicy, logs in
+// to mint a token, and caches the generated credentials so the renewal daemon can
+// log in again later if the token stops being renewable.
+func (p *fileTokenProvider) bootstrapUserPass(
+	serviceName string,
+	servicePolicy map[string]interface{},
+	privilegedToken string,
+	userManager common.UserManager,
+	credentialGenerator secretstore.CredentialGenerator) (interface{}, error) {
+
+	randomPassword, err := credentialGenerator.Generate(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := userManager.CreatePasswordUserWithPolicy(serviceName, randomPassword, "edgex-service-", servicePolicy); err != nil {
+		return nil, err
+	}
+
+	p.stateMutex.Lock()
+	p.credentials[serviceName] = userPassRelogin{username: serviceName, password: randomPassword}
+	p.stateMutex.Unlock()
+
+	return p.secretStoreClient.InternalServiceLogin(privilegedToken, p.tokenConfig.UserPassMountPoint, serviceName, randomPassword)
+}
+
+// bootstrapAppRole provisions a Vault approle role bound to servicePolicy, writes
+// the resulting role_id/secret_id pair to disk alongside the token file, caches
+// them so the renewal daemon can log back in later, and logs in once to mint the
+// service's initial token.
+//
+// go-mod-secrets' secrets.SecretStoreClient has no approle support, so this talks
+// to Vault's HTTP API directly via vaultAuthClient rather than going through
+// p.secretStoreClient, the same way the k8s-secret sink talks to the Kubernetes
+// API server directly instead of depending on client-go.
+func (p *fileTokenProvider) bootstrapAppRole(
+	serviceName string,
+	servicePolicy map[string]interface{},
+	privilegedToken string,
+	filePermissions *config.FilePermissions) (interface{}, error) {
+
+	vaultClient := newVaultAuthClient(p.secretStoreConfig)
+
+	if err := vaultClient.createPolicy(privilegedToken, serviceName, policyToHCL(servicePolicy)); err != nil {
+		return nil, fmt.Errorf("failed to provision policy for service %s: %w", serviceName, err)
+	}
+
+	if err := vaultClient.createAppRole(privilegedToken, serviceName, serviceName, p.tokenConfig.DefaultTokenTTL); err != nil {
+		return nil, fmt.Errorf("failed to provision approle role for service %s: %w", serviceName, err)
+	}
+
+	roleID, err := vaultClient.readAppRoleID(privilegedToken, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read role_id for service %s: %w", serviceName, err)
+	}
+
+	secretID, err := vaultClient.generateAppRoleSecretID(privilegedToken, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret_id for service %s: %w", serviceName, err)
+	}
+
+	approleCredentials := map[string]string{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	}
+	if err := p.writeJSONFile(serviceName, approleCredentialsFilename, filePermissions, approleCredentials); err != nil {
+		return nil, fmt.Errorf("failed to write approle credentials for service %s: %w", serviceName, err)
+	}
+
+	p.stateMutex.Lock()
+	p.credentials[serviceName] = appRoleRelogin{roleID: roleID, secretID: secretID}
+	p.stateMutex.Unlock()
+
+	return vaultClient.appRoleLogin(roleID, secretID)
+}
+
+// bootstrapJWT registers a Vault jwt role bound to servicePolicy and the service's
+// configured issuer/audience. No password or token file is created here -- the
+// service is expected to exchange its own workload-identity JWT for a Vault token
+// at runtime.
+//
+// Same rationale as bootstrapAppRole: jwt role provisioning isn't exposed by
+// secrets.SecretStoreClient, so this goes through vaultAuthClient instead.
+func (p *fileTokenProvider) bootstrapJWT(
+	serviceName string,
+	servicePolicy map[string]interface{},
+	privilegedToken string,
+	serviceConfig ServiceInfo) (interface{}, error) {
+
+	jwtAuth := serviceConfig.JWTAuth
+	if jwtAuth == nil {
+		return nil, fmt.Errorf("service %s selected auth method %q but has no JWTAuth configuration", serviceName, authMethodJWT)
+	}
+
+	vaultClient := newVaultAuthClient(p.secretStoreConfig)
+
+	if err := vaultClient.createPolicy(privilegedToken, serviceName, policyToHCL(servicePolicy)); err != nil {
+		return nil, fmt.Errorf("failed to provision policy for service %s: %w", serviceName, err)
+	}
+
+	if err := vaultClient.createJWTRole(privilegedToken, serviceName, serviceName, jwtAuth.Issuer, jwtAuth.Audience); err != nil {
+		return nil, fmt.Errorf("failed to provision jwt role for service %s: %w", serviceName, err)
+	}
+
+	p.logger.Infof("jwt role for service %s registered; service will exchange its own JWT for a Vault token at runtime", serviceName)
+	return nil, nil
+}
@@ -0,0 +1,38 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"github.com/edgexfoundry/go-mod-secrets/v3/secrets"
+)
+
+// tokenRenewer is implemented by secrets.SecretStoreClient implementations that
+// also support renewing a previously issued token. It is its own interface,
+// rather than an addition to secrets.SecretStoreClient, because that type comes
+// from the pinned go-mod-secrets module and isn't ours to extend; callers type-
+// assert against tokenRenewer and fall back to a fresh login when a client
+// doesn't implement it.
+//
+// The pinned go-mod-secrets/v3 secrets.SecretStoreClient does not implement this
+// today, so in practice renewOrRelogin always takes the reloginAndWrite fallback
+// path rather than renewing in place -- this interface exists so that renewal
+// starts working for free the moment a client gains RenewToken, without this
+// package needing to change. Until then, treat renewal as relogin-only.
+type tokenRenewer interface {
+	secrets.SecretStoreClient
+	RenewToken(token string, increment int) (interface{}, error)
+}
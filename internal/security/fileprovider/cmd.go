@@ -0,0 +1,48 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-secrets/v3/pkg/token/fileioperformer"
+)
+
+// SignTokenConfigCommand implements the `secrets-config sign-token-config`
+// subcommand: it signs a token config file with a PEM/PKCS8 RSA private key and
+// writes the resulting detached JWS to <config>.sig. args is the subcommand's
+// own argument list, not including the subcommand name itself.
+//
+// This is the subcommand's logic only -- there is no cmd/secrets-config main
+// package in this tree yet to dispatch "secrets-config sign-token-config" into
+// it, so until that entrypoint exists, callers reach this directly (see
+// cmd_test.go) rather than through a CLI.
+func SignTokenConfigCommand(fileOpener fileioperformer.FileIoPerformer, args []string) error {
+	flagSet := flag.NewFlagSet("sign-token-config", flag.ContinueOnError)
+	configPath := flagSet.String("config", "", "path to the token config file to sign")
+	keyPath := flagSet.String("key", "", "path to the PEM/PKCS8 RSA private key to sign with")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *configPath == "" || *keyPath == "" {
+		return fmt.Errorf("sign-token-config requires both -config and -key")
+	}
+
+	return SignTokenConfigFile(fileOpener, *configPath, *keyPath)
+}
@@ -0,0 +1,79 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-secrets/v3/pkg/token/fileioperformer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// osFileIoPerformer implements fileioperformer.FileIoPerformer by delegating to
+// the os package, for exercising SignTokenConfigCommand against the real
+// filesystem in tests without depending on a mock of the external interface.
+type osFileIoPerformer struct{}
+
+func (osFileIoPerformer) OpenFileReader(name string, flag int, perm os.FileMode) (interface {
+	Read([]byte) (int, error)
+}, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFileIoPerformer) OpenFileWriter(name string, flag int, perm os.FileMode) (fileioperformer.WriteCloser, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFileIoPerformer) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func TestSignTokenConfigCommand_WritesVerifiableSignature(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "token-config.json")
+	keyPath := filepath.Join(dir, "signing-key.pem")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"service-a":{"useDefaults":true}}`), 0600))
+
+	key := testRSAKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+	require.NoError(t, os.WriteFile(keyPath, keyPEM, 0600))
+
+	err = SignTokenConfigCommand(osFileIoPerformer{}, []string{"-config", configPath, "-key", keyPath})
+	require.NoError(t, err)
+
+	payload, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	serialized, err := os.ReadFile(configPath + detachedSignatureSuffix)
+	require.NoError(t, err)
+
+	err = verifyDetachedSignature(string(serialized), payload, []crypto.PublicKey{&key.PublicKey})
+	assert.NoError(t, err)
+}
+
+func TestSignTokenConfigCommand_RequiresConfigAndKeyFlags(t *testing.T) {
+	err := SignTokenConfigCommand(osFileIoPerformer{}, []string{"-config", "/tmp/only-config.json"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package config
+
+import "time"
+
+// FilePermissions controls the mode/ownership applied to a file this provider
+// writes to disk.
+type FilePermissions struct {
+	ModeOctal *string
+	Uid       *int
+	Gid       *int
+}
+
+// TokenFileProviderInfo configures the file token provider.
+type TokenFileProviderInfo struct {
+	PrivilegedTokenPath string
+	ConfigFile          string
+	OutputDir           string
+	OutputFilename      string
+	UserPassMountPoint  string
+	DefaultTokenTTL     string
+	DefaultJWTTTL       string
+
+	// RenewEnabled keeps the provider resident after the initial bootstrap to
+	// renew each service's token before it expires.
+	RenewEnabled bool
+	// RenewEarlyDuration is how long before expiry a token is renewed.
+	RenewEarlyDuration time.Duration
+	// RenewMaxRetries caps renewal attempts before falling back to a fresh login.
+	RenewMaxRetries int
+
+	// TrustedSignersDir holds the public keys ConfigFile's detached signature is
+	// verified against when RequireSignedConfig is set.
+	TrustedSignersDir string
+
+	// StrictTemplateMode makes a policy template referencing an undefined
+	// variable fail the render instead of silently producing "<no value>".
+	StrictTemplateMode bool
+}
+
+// SinkInfo selects where a service's minted token is delivered. Type defaults to
+// "file" when unset.
+type SinkInfo struct {
+	Type      string
+	Namespace string
+	Name      string
+}
+
+// JWTAuthInfo configures the Vault jwt auth role registered for a service using
+// authMethodJWT.
+type JWTAuthInfo struct {
+	Issuer   string
+	Audience string
+}
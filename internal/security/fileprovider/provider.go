@@ -19,9 +19,13 @@ package fileprovider
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/edgexfoundry/edgex-go/internal/security/common"
 	securityCommon "github.com/edgexfoundry/edgex-go/internal/security/common"
@@ -42,6 +46,20 @@ type permissionable interface {
 	Chmod(mode os.FileMode) error
 }
 
+// vaultTokenAuth models the subset of a Vault-compatible token response this provider
+// needs in order to decide when a token should be renewed.
+type vaultTokenAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// vaultTokenResponse is the envelope wrapping vaultTokenAuth, matching the shape
+// returned by both token-creation and token-renewal calls.
+type vaultTokenResponse struct {
+	Auth vaultTokenAuth `json:"auth"`
+}
+
 // fileTokenProvider stores instance data
 type fileTokenProvider struct {
 	logger            logger.LoggingClient
@@ -50,6 +68,13 @@ type fileTokenProvider struct {
 	secretStoreClient secrets.SecretStoreClient
 	secretStoreConfig secretstoreConfig.SecretStoreInfo
 	tokenConfig       config.TokenFileProviderInfo
+
+	stateMutex  sync.Mutex
+	credentials map[string]serviceRelogin
+	sinks       map[string]TokenSink
+	lastAuth    map[string]vaultTokenAuth
+
+	renderOnly bool
 }
 
 // NewTokenProvider creates a new TokenProvider
@@ -62,6 +87,9 @@ func NewTokenProvider(logger logger.LoggingClient,
 		fileOpener:        fileOpener,
 		tokenProvider:     tokenProvider,
 		secretStoreClient: secretStoreClient,
+		credentials:       make(map[string]serviceRelogin),
+		sinks:             make(map[string]TokenSink),
+		lastAuth:          make(map[string]vaultTokenAuth),
 	}
 }
 
@@ -71,26 +99,81 @@ func (p *fileTokenProvider) SetConfiguration(secretStoreConfig secretstoreConfig
 	p.tokenConfig = tokenConfig
 }
 
+// SetRenderOnly controls whether Run/RunDaemon only print each service's expanded
+// policy JSON instead of contacting Vault, backing the CLI's --render-only mode so
+// operators can review what a templated policy expands to before applying it.
+func (p *fileTokenProvider) SetRenderOnly(renderOnly bool) {
+	p.renderOnly = renderOnly
+}
+
 // Do whatever is needed
 func (p *fileTokenProvider) Run() error {
+	_, err := p.generateTokens()
+	return err
+}
+
+// RunDaemon performs the bootstrap done by Run, then blocks renewing each
+// service's token in the background until ctx is canceled, if renewal is enabled.
+func (p *fileTokenProvider) RunDaemon(ctx context.Context) error {
+	tokenConf, err := p.generateTokens()
+	if err != nil {
+		return err
+	}
+
+	if !p.tokenConfig.RenewEnabled {
+		p.logger.Info("token renewal disabled, exiting after initial bootstrap")
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for serviceName, serviceConfig := range tokenConf {
+		wg.Add(1)
+		go func(serviceName string, serviceConfig ServiceInfo) {
+			defer wg.Done()
+			p.renewalLoop(ctx, serviceName, serviceConfig)
+		}(serviceName, serviceConfig)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// generateTokens performs the one-shot bootstrap: it creates (or refreshes) the Vault
+// userpass user for each configured service, logs in to mint a token, and writes that
+// token to disk. It returns the merged token configuration so RunDaemon can reuse it
+// to start per-service renewal goroutines without re-parsing the config.
+func (p *fileTokenProvider) generateTokens() (TokenConfFile, error) {
 	p.logger.Info("Generating Vault tokens")
 
 	privilegedToken, err := p.tokenProvider.Load(p.tokenConfig.PrivilegedTokenPath)
 	if err != nil {
 		p.logger.Errorf("failed to read privileged access token: %s", err.Error())
-		return err
+		return nil, err
 	}
 
 	tokenConfEnv, err := GetTokenConfigFromEnv()
 	if err != nil {
 		p.logger.Errorf("failed to get token config from environment variable %s with error: %s", addSecretstoreTokensEnvKey, err.Error())
-		return err
+		return nil, err
+	}
+
+	if p.secretStoreConfig.RequireSignedConfig {
+		if len(tokenConfEnv) > 0 {
+			err := fmt.Errorf("%s is set but RequireSignedConfig is enabled; environment-provided token config cannot be signature-verified", addSecretstoreTokensEnvKey)
+			p.logger.Errorf("%s", err.Error())
+			return nil, err
+		}
+
+		if err := verifyConfigSignature(p.fileOpener, p.tokenConfig.ConfigFile, p.tokenConfig.TrustedSignersDir); err != nil {
+			p.logger.Errorf("refusing to mint tokens from unverified config %s: %s", p.tokenConfig.ConfigFile, err.Error())
+			return nil, err
+		}
 	}
 
 	var tokenConf TokenConfFile
 	if err := LoadTokenConfig(p.fileOpener, p.tokenConfig.ConfigFile, &tokenConf); err != nil {
 		p.logger.Errorf("failed to read token configuration file %s: %s", p.tokenConfig.ConfigFile, err.Error())
-		return err
+		return nil, err
 	}
 
 	// merge the additional token configuration list from environment variable
@@ -131,84 +214,288 @@ func (p *fileTokenProvider) Run() error {
 			}
 		}
 
-		// Generate a random password
-
-		randomPassword, err := credentialGenerator.Generate(context.TODO())
+		vars := newPolicyTemplateVars(serviceName, p.secretStoreConfig.Namespace)
+		servicePolicy, err = renderPolicyTemplate(servicePolicy, vars, p.tokenConfig.StrictTemplateMode)
 		if err != nil {
-			return err
+			p.logger.Errorf("failed to render policy template for service %s: %s", serviceName, err.Error())
+			return nil, err
+		}
+
+		if p.renderOnly {
+			renderedJSON, err := json.MarshalIndent(servicePolicy, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			p.logger.Infof("rendered policy for service %s:\n%s", serviceName, string(renderedJSON))
+			continue
 		}
 
-		// Create a user with the random password
+		authMethod := serviceConfig.AuthMethod
+		if authMethod == "" {
+			authMethod = authMethodUserPass
+		}
 
-		err = userManager.CreatePasswordUserWithPolicy(serviceName, randomPassword, "edgex-service-", servicePolicy)
+		var createTokenResponse interface{}
+		switch authMethod {
+		case authMethodUserPass:
+			createTokenResponse, err = p.bootstrapUserPass(serviceName, servicePolicy, privilegedToken, userManager, credentialGenerator)
+		case authMethodAppRole:
+			createTokenResponse, err = p.bootstrapAppRole(serviceName, servicePolicy, privilegedToken, serviceConfig.FilePermissions)
+		case authMethodJWT:
+			createTokenResponse, err = p.bootstrapJWT(serviceName, servicePolicy, privilegedToken, serviceConfig)
+		default:
+			err = fmt.Errorf("unknown auth method %q for service %s", authMethod, serviceName)
+		}
 		if err != nil {
-			return err
+			// A single misconfigured or unreachable service must not take every
+			// other service in the file down with it: log, drop it from the
+			// result so RunDaemon doesn't try to renew a token it never minted,
+			// and move on to the next service.
+			p.logger.Errorf("failed to bootstrap service %s, skipping: %s", serviceName, err.Error())
+			delete(tokenConf, serviceName)
+			continue
 		}
 
-		// Immediately log in the user to get a vault token
+		// A jwt bootstrap only registers the role in Vault -- the service itself
+		// exchanges its workload-identity JWT for a token at runtime, so there is
+		// no token to deliver to a sink here.
+		if createTokenResponse == nil {
+			continue
+		}
 
-		var createTokenResponse interface{}
-		if createTokenResponse, err = p.secretStoreClient.InternalServiceLogin(privilegedToken, p.tokenConfig.UserPassMountPoint, serviceName, randomPassword); err != nil {
-			return err
+		sink, err := newTokenSink(p, serviceConfig)
+		if err != nil {
+			p.logger.Errorf("failed to build token sink for service %s: %s", serviceName, err.Error())
+			return nil, err
+		}
+
+		if err := sink.Write(context.TODO(), serviceName, createTokenResponse); err != nil {
+			return nil, err
 		}
 
-		// Serialize the vault token to disk
+		p.stateMutex.Lock()
+		p.sinks[serviceName] = sink
+		p.stateMutex.Unlock()
 
-		outputTokenDir := filepath.Join(p.tokenConfig.OutputDir, serviceName)
-		outputTokenFilename := filepath.Join(outputTokenDir, p.tokenConfig.OutputFilename)
-		if err := p.fileOpener.MkdirAll(outputTokenDir, os.FileMode(0700)); err != nil {
-			p.logger.Errorf("failed to create base directory path(s) %s: %s", outputTokenDir, err.Error())
-			return err
+		if auth, err := tokenAuthFromResponse(createTokenResponse); err == nil {
+			p.stateMutex.Lock()
+			p.lastAuth[serviceName] = auth
+			p.stateMutex.Unlock()
 		}
+	}
 
-		p.logger.Infof("creating token file %s", outputTokenFilename)
-		writeCloser, err := p.fileOpener.OpenFileWriter(outputTokenFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0600))
-		if err != nil {
-			p.logger.Errorf("failed open token file for writing %s: %s", outputTokenFilename, err.Error())
-			return err
-		}
-		// writeCloser is writable file -- explicitly close() to ensure we catch errors writing to it
-
-		permissionable, ok := writeCloser.(permissionable)
-		if ok {
-			if serviceConfig.FilePermissions != nil &&
-				(serviceConfig.FilePermissions).ModeOctal != nil {
-				mode, err := strconv.ParseInt(*(serviceConfig.FilePermissions).ModeOctal, 8, 32)
-				if err != nil {
-					_ = writeCloser.Close()
-					p.logger.Errorf("invalid file mode %s: %s", *(serviceConfig.FilePermissions).ModeOctal, err.Error())
-					return err
-				}
-				if err := permissionable.Chmod(os.FileMode(mode)); err != nil {
-					_ = writeCloser.Close()
-					p.logger.Errorf("failed to set file mode on %s: %s", outputTokenFilename, err.Error())
-					return err
-				}
+	return tokenConf, nil
+}
+
+// writeTokenFile serializes token to the service's token file, applying the
+// configured permissions/ownership.
+func (p *fileTokenProvider) writeTokenFile(serviceName string, filePermissions *config.FilePermissions, token interface{}) error {
+	return p.writeJSONFile(serviceName, p.tokenConfig.OutputFilename, filePermissions, token)
+}
+
+// writeJSONFile serializes data as JSON to OutputDir/<service>/<filename>, applying
+// the configured permissions/ownership. The file is written to a temporary path in
+// the same directory and then renamed into place so that consumers never observe a
+// partially-written file.
+func (p *fileTokenProvider) writeJSONFile(serviceName string, filename string, filePermissions *config.FilePermissions, data interface{}) error {
+	outputDir := filepath.Join(p.tokenConfig.OutputDir, serviceName)
+	outputFilename := filepath.Join(outputDir, filename)
+	if err := p.fileOpener.MkdirAll(outputDir, os.FileMode(0700)); err != nil {
+		p.logger.Errorf("failed to create base directory path(s) %s: %s", outputDir, err.Error())
+		return err
+	}
+
+	tmpFilename := outputFilename + ".tmp"
+
+	p.logger.Infof("creating file %s", outputFilename)
+	writeCloser, err := p.fileOpener.OpenFileWriter(tmpFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0600))
+	if err != nil {
+		p.logger.Errorf("failed open file for writing %s: %s", tmpFilename, err.Error())
+		return err
+	}
+	// writeCloser is writable file -- explicitly close() to ensure we catch errors writing to it
+
+	permissionable, ok := writeCloser.(permissionable)
+	if ok {
+		if filePermissions != nil && filePermissions.ModeOctal != nil {
+			mode, err := strconv.ParseInt(*filePermissions.ModeOctal, 8, 32)
+			if err != nil {
+				_ = writeCloser.Close()
+				p.logger.Errorf("invalid file mode %s: %s", *filePermissions.ModeOctal, err.Error())
+				return err
 			}
-			if serviceConfig.FilePermissions != nil &&
-				(serviceConfig.FilePermissions).Uid != nil &&
-				(serviceConfig.FilePermissions).Gid != nil {
-				err := permissionable.Chown(*(serviceConfig.FilePermissions).Uid, *(serviceConfig.FilePermissions).Gid)
-				if err != nil {
-					_ = writeCloser.Close()
-					p.logger.Errorf("failed to set file user/group on %s: %s", outputTokenFilename, err.Error())
-					return err
-				}
+			if err := permissionable.Chmod(os.FileMode(mode)); err != nil {
+				_ = writeCloser.Close()
+				p.logger.Errorf("failed to set file mode on %s: %s", tmpFilename, err.Error())
+				return err
+			}
+		}
+		if filePermissions != nil && filePermissions.Uid != nil && filePermissions.Gid != nil {
+			err := permissionable.Chown(*filePermissions.Uid, *filePermissions.Gid)
+			if err != nil {
+				_ = writeCloser.Close()
+				p.logger.Errorf("failed to set file user/group on %s: %s", tmpFilename, err.Error())
+				return err
 			}
 		}
+	}
+
+	if err := json.NewEncoder(writeCloser).Encode(data); err != nil {
+		_ = writeCloser.Close()
+		p.logger.Errorf("failed to write file: %s", err.Error())
+		return err
+	}
+
+	if err := writeCloser.Close(); err != nil {
+		p.logger.Errorf("failed to close %s: %s", tmpFilename, err.Error())
+		return err
+	}
+
+	if err := os.Rename(tmpFilename, outputFilename); err != nil {
+		p.logger.Errorf("failed to move %s into place at %s: %s", tmpFilename, outputFilename, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// renewalLoop renews serviceName's Vault token shortly before it expires, rewriting
+// the token file in place, until ctx is canceled. If the token becomes non-renewable
+// it falls back to a fresh userpass login using the credentials cached at bootstrap.
+func (p *fileTokenProvider) renewalLoop(ctx context.Context, serviceName string, serviceConfig ServiceInfo) {
+	for {
+		auth, err := p.currentTokenAuth(serviceName)
+		if err != nil {
+			p.logger.Errorf("renewal for service %s: unable to determine token lease, stopping: %s", serviceName, err.Error())
+			return
+		}
 
-		// Write resulting token
-		if err := json.NewEncoder(writeCloser).Encode(createTokenResponse); err != nil {
-			_ = writeCloser.Close()
-			p.logger.Errorf("failed to write token file: %s", err.Error())
-			return err
+		renewAt := p.nextRenewalDelay(auth.LeaseDuration)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(renewAt):
 		}
 
-		if err := writeCloser.Close(); err != nil {
-			p.logger.Errorf("failed to close %s: %s", outputTokenFilename, err.Error())
-			return err
+		if err := p.renewOrRelogin(serviceName, auth, serviceConfig); err != nil {
+			p.logger.Errorf("renewal for service %s failed, stopping renewal: %s", serviceName, err.Error())
+			return
 		}
 	}
+}
+
+// nextRenewalDelay returns how long to wait before renewing a token with the given
+// lease duration, renewing RenewEarlyDuration before expiry with a small jitter so
+// that many services don't all hit Vault in the same instant.
+func (p *fileTokenProvider) nextRenewalDelay(leaseDurationSeconds int) time.Duration {
+	lease := time.Duration(leaseDurationSeconds) * time.Second
+	delay := lease - p.tokenConfig.RenewEarlyDuration
+	if delay < 0 {
+		delay = 0
+	}
+	jitter := time.Duration(rand.Int63n(int64(time.Second)))
+	return delay + jitter
+}
+
+// renewOrRelogin attempts to renew serviceName's existing token up to RenewMaxRetries
+// times (at least once, regardless of how RenewMaxRetries is configured) with
+// backoff, falling back to a fresh login when the token reports itself as
+// non-renewable, the client doesn't support renewal, or renewal keeps failing.
+//
+// The pinned go-mod-secrets/v3 client does not implement tokenRenewer, so as
+// shipped every renewal takes the relogin fallback below rather than a real
+// Vault-side RenewToken call -- this is a relogin-only renewal implementation
+// for now, not the renew-with-backoff behavior the interface's doc comment
+// describes, pending that capability landing in go-mod-secrets.
+func (p *fileTokenProvider) renewOrRelogin(serviceName string, auth vaultTokenAuth, serviceConfig ServiceInfo) error {
+	renewer, ok := p.secretStoreClient.(tokenRenewer)
+	if !ok || !auth.Renewable {
+		p.logger.Infof("token for service %s cannot be renewed, logging in again", serviceName)
+		return p.reloginAndWrite(serviceName, serviceConfig)
+	}
+
+	maxAttempts := p.tokenConfig.RenewMaxRetries
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	backoff := time.Second
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		renewResponse, err := renewer.RenewToken(auth.ClientToken, 0)
+		if err == nil {
+			return p.deliverToken(serviceName, renewResponse)
+		}
+
+		lastErr = err
+		p.logger.Errorf("renew attempt %d for service %s failed: %s", attempt+1, serviceName, err.Error())
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	p.logger.Errorf("exhausted renewal retries for service %s, logging in again: %s", serviceName, lastErr.Error())
+	return p.reloginAndWrite(serviceName, serviceConfig)
+}
+
+// reloginAndWrite performs a fresh login -- using whichever auth method cached
+// credentials at bootstrap time -- and delivers the resulting token to the
+// service's sink.
+func (p *fileTokenProvider) reloginAndWrite(serviceName string, serviceConfig ServiceInfo) error {
+	p.stateMutex.Lock()
+	relogin, ok := p.credentials[serviceName]
+	p.stateMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("no cached credentials for service %s, cannot re-login", serviceName)
+	}
+
+	privilegedToken, err := p.tokenProvider.Load(p.tokenConfig.PrivilegedTokenPath)
+	if err != nil {
+		return err
+	}
+
+	createTokenResponse, err := relogin.relogin(p, privilegedToken)
+	if err != nil {
+		return err
+	}
+
+	return p.deliverToken(serviceName, createTokenResponse)
+}
+
+// deliverToken writes token via the service's configured sink and refreshes the
+// cached lease info used to schedule the next renewal.
+func (p *fileTokenProvider) deliverToken(serviceName string, token interface{}) error {
+	p.stateMutex.Lock()
+	sink := p.sinks[serviceName]
+	p.stateMutex.Unlock()
+	if sink == nil {
+		return fmt.Errorf("no token sink configured for service %s", serviceName)
+	}
+
+	if err := sink.Write(context.TODO(), serviceName, token); err != nil {
+		return err
+	}
+
+	auth, err := tokenAuthFromResponse(token)
+	if err != nil {
+		return err
+	}
+
+	p.stateMutex.Lock()
+	p.lastAuth[serviceName] = auth
+	p.stateMutex.Unlock()
 
 	return nil
 }
+
+// currentTokenAuth returns the most recently delivered token's lease info for
+// serviceName, used to schedule the next renewal.
+func (p *fileTokenProvider) currentTokenAuth(serviceName string) (vaultTokenAuth, error) {
+	p.stateMutex.Lock()
+	auth, ok := p.lastAuth[serviceName]
+	p.stateMutex.Unlock()
+	if !ok {
+		return vaultTokenAuth{}, fmt.Errorf("no known token lease for service %s", serviceName)
+	}
+
+	return auth, nil
+}
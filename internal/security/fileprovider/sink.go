@@ -0,0 +1,316 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/security/fileprovider/config"
+	"github.com/edgexfoundry/go-mod-secrets/v3/pkg/token/fileioperformer"
+)
+
+// TokenSink delivers a service's minted Vault token to wherever that service's
+// configuration directs it -- a local file, a Kubernetes Secret, a dotenv file, etc.
+type TokenSink interface {
+	Write(ctx context.Context, serviceName string, token interface{}) error
+}
+
+// newTokenSink builds the TokenSink configured for a service, defaulting to the
+// existing file sink when the service has no Sink configuration.
+func newTokenSink(p *fileTokenProvider, serviceConfig ServiceInfo) (TokenSink, error) {
+	sinkConfig := serviceConfig.Sink
+	if sinkConfig == nil || sinkConfig.Type == "" || sinkConfig.Type == "file" {
+		return &fileTokenSink{provider: p, filePermissions: serviceConfig.FilePermissions}, nil
+	}
+
+	switch sinkConfig.Type {
+	case "k8s-secret":
+		return newK8sSecretTokenSink(p, sinkConfig)
+	case "env-file":
+		return &envFileTokenSink{provider: p, sinkConfig: sinkConfig}, nil
+	default:
+		return nil, fmt.Errorf("unknown token sink type %q", sinkConfig.Type)
+	}
+}
+
+// fileTokenSink is the original token delivery mechanism: the full token JSON
+// written to OutputDir/<service>/OutputFilename with the configured permissions.
+type fileTokenSink struct {
+	provider        *fileTokenProvider
+	filePermissions *config.FilePermissions
+}
+
+func (s *fileTokenSink) Write(_ context.Context, serviceName string, token interface{}) error {
+	return s.provider.writeTokenFile(serviceName, s.filePermissions, token)
+}
+
+// Paths of the service account credentials Kubernetes projects into every pod,
+// used by k8sSecretTokenSink instead of pulling in client-go.
+const (
+	serviceAccountTokenPath     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCACertPath    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// k8sSecretTokenSink upserts the token into a Kubernetes Secret over the in-cluster
+// API server, so deployments on Kubernetes can consume tokens via a projected
+// Secret volume instead of a hostPath mount. It talks to the API server directly
+// over net/http with the pod's mounted service account credentials rather than
+// depending on client-go/k8s.io/api, which this module doesn't otherwise need.
+//
+// The bearer token and CA bundle are re-read from disk on every Write rather than
+// cached at construction: Kubernetes rotates projected service account tokens on
+// disk roughly every hour, and this sink is built once in generateTokens but kept
+// alive and reused for every renewal delivered through RunDaemon's renewal loop.
+type k8sSecretTokenSink struct {
+	provider  *fileTokenProvider
+	namespace string
+	name      string
+	baseURL   string
+}
+
+func newK8sSecretTokenSink(p *fileTokenProvider, sinkConfig *config.SinkInfo) (*k8sSecretTokenSink, error) {
+	namespace := sinkConfig.Namespace
+	if namespace == "" {
+		namespaceBytes, err := readFile(p.fileOpener, serviceAccountNamespacePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine namespace for k8s-secret sink: %w", err)
+		}
+		namespace = strings.TrimSpace(string(namespaceBytes))
+	}
+
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("KUBERNETES_SERVICE_HOST/KUBERNETES_SERVICE_PORT not set; k8s-secret sink requires running in-cluster")
+	}
+
+	// Fail fast if the credentials aren't readable yet, even though Write re-reads
+	// them itself -- better to catch a missing/unmounted service account here than
+	// after the first bootstrap has already minted a token with nowhere to go.
+	if _, _, err := readServiceAccountCredentials(p.fileOpener); err != nil {
+		return nil, err
+	}
+
+	return &k8sSecretTokenSink{
+		provider:  p,
+		namespace: namespace,
+		name:      sinkConfig.Name,
+		baseURL:   "https://" + net.JoinHostPort(host, port),
+	}, nil
+}
+
+// readServiceAccountCredentials reads the pod's current bearer token and CA bundle
+// fresh, returning an *http.Client trusting that CA and the token string to send as
+// the Authorization header.
+func readServiceAccountCredentials(fileOpener fileioperformer.FileIoPerformer) (*http.Client, string, error) {
+	token, err := readFile(fileOpener, serviceAccountTokenPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read in-cluster service account token: %w", err)
+	}
+
+	caCert, err := readFile(fileOpener, serviceAccountCACertPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read in-cluster service account CA certificate: %w", err)
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, "", fmt.Errorf("failed to parse in-cluster service account CA certificate")
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}},
+		Timeout:   10 * time.Second,
+	}
+
+	return client, strings.TrimSpace(string(token)), nil
+}
+
+// k8sSecret is the subset of the Kubernetes Secret object this sink reads/writes.
+type k8sSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   k8sObjectMeta     `json:"metadata"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data,omitempty"`
+}
+
+type k8sObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+func (s *k8sSecretTokenSink) Write(ctx context.Context, serviceName string, token interface{}) error {
+	client, bearerToken, err := readServiceAccountCredentials(s.provider.fileOpener)
+	if err != nil {
+		return fmt.Errorf("failed to read current in-cluster credentials for service %s: %w", serviceName, err)
+	}
+
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token for service %s: %w", serviceName, err)
+	}
+
+	secretName := s.name
+	if secretName == "" {
+		secretName = serviceName + "-vault-token"
+	}
+
+	collectionURL := fmt.Sprintf("%s/api/v1/namespaces/%s/secrets", s.baseURL, s.namespace)
+	itemURL := collectionURL + "/" + secretName
+
+	existing, err := s.get(ctx, client, bearerToken, itemURL)
+	if err != nil {
+		return fmt.Errorf("failed to look up secret %s/%s: %w", s.namespace, secretName, err)
+	}
+
+	secret := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   k8sObjectMeta{Name: secretName, Namespace: s.namespace},
+		Type:       "Opaque",
+		Data:       map[string][]byte{"token.json": tokenJSON},
+	}
+
+	if existing == nil {
+		return s.send(ctx, client, bearerToken, http.MethodPost, collectionURL, secret)
+	}
+
+	secret.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	return s.send(ctx, client, bearerToken, http.MethodPut, itemURL, secret)
+}
+
+// get fetches the named secret, returning (nil, nil) if it doesn't exist yet.
+func (s *k8sSecretTokenSink) get(ctx context.Context, client *http.Client, bearerToken string, url string) (*k8sSecret, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var secret k8sSecret
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (s *k8sSecretTokenSink) send(ctx context.Context, client *http.Client, bearerToken string, method string, url string, secret k8sSecret) error {
+	body, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// envFileTokenSink writes the token as a shell-sourceable VAULT_TOKEN=... file.
+type envFileTokenSink struct {
+	provider   *fileTokenProvider
+	sinkConfig *config.SinkInfo
+}
+
+func (s *envFileTokenSink) Write(_ context.Context, serviceName string, token interface{}) error {
+	auth, err := tokenAuthFromResponse(token)
+	if err != nil {
+		return fmt.Errorf("failed to read token for env-file sink: %w", err)
+	}
+
+	outputDir := filepath.Join(s.provider.tokenConfig.OutputDir, serviceName)
+	if err := s.provider.fileOpener.MkdirAll(outputDir, os.FileMode(0700)); err != nil {
+		return err
+	}
+
+	filename := s.sinkConfig.Name
+	if filename == "" {
+		filename = "secrets-token.env"
+	}
+	outputPath := filepath.Join(outputDir, filename)
+
+	writeCloser, err := s.provider.fileOpener.OpenFileWriter(outputPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0600))
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(writeCloser, "VAULT_TOKEN=%s\n", auth.ClientToken); err != nil {
+		_ = writeCloser.Close()
+		return err
+	}
+
+	return writeCloser.Close()
+}
+
+// tokenAuthFromResponse round-trips an opaque token response through JSON to pull
+// out the fields sinks and the renewal loop care about.
+func tokenAuthFromResponse(token interface{}) (vaultTokenAuth, error) {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return vaultTokenAuth{}, err
+	}
+
+	var response vaultTokenResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return vaultTokenAuth{}, err
+	}
+
+	return response.Auth, nil
+}
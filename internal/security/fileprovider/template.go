@@ -0,0 +1,164 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// policyTemplateVars is the variable set available when expanding a {{ ... }}
+// template embedded in a policy path or capability string.
+type policyTemplateVars struct {
+	ServiceName string
+	Env         map[string]string
+	SecretStore secretStoreTemplateVars
+	UUID        string
+	RandomID    string
+}
+
+// secretStoreTemplateVars exposes secret store details under .SecretStore.
+type secretStoreTemplateVars struct {
+	Namespace string
+}
+
+// newPolicyTemplateVars builds the variable set for serviceName. UUID and RandomID
+// are generated once per service so that a policy referencing them more than once
+// gets a consistent value within that service's render.
+func newPolicyTemplateVars(serviceName string, namespace string) policyTemplateVars {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if idx := strings.IndexByte(entry, '='); idx >= 0 {
+			env[entry[:idx]] = entry[idx+1:]
+		}
+	}
+
+	id := newUUIDv4()
+	return policyTemplateVars{
+		ServiceName: serviceName,
+		Env:         env,
+		SecretStore: secretStoreTemplateVars{Namespace: namespace},
+		UUID:        id,
+		RandomID:    id[:8],
+	}
+}
+
+// newUUIDv4 generates a random (version 4, variant 10) UUID per RFC 4122 §4.4.
+func newUUIDv4() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes for UUID: %s", err.Error()))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// renderPolicyTemplate expands every path key and capability string under policy's
+// "path" entry using Go's text/template, substituting vars. In strict mode,
+// referencing an undefined variable fails the render instead of silently producing
+// "<no value>".
+func renderPolicyTemplate(policy map[string]interface{}, vars policyTemplateVars, strict bool) (map[string]interface{}, error) {
+	paths, ok := policy["path"].(map[string]interface{})
+	if !ok {
+		return policy, nil
+	}
+
+	renderedPaths := make(map[string]interface{}, len(paths))
+	for pathKey, pathValue := range paths {
+		renderedKey, err := expandTemplate(pathKey, vars, strict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render policy path %q: %w", pathKey, err)
+		}
+
+		renderedValue, err := renderPathCapabilities(pathValue, vars, strict)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render capabilities for path %q: %w", pathKey, err)
+		}
+
+		renderedPaths[renderedKey] = renderedValue
+	}
+
+	rendered := make(map[string]interface{}, len(policy))
+	for key, value := range policy {
+		rendered[key] = value
+	}
+	rendered["path"] = renderedPaths
+
+	return rendered, nil
+}
+
+// renderPathCapabilities expands every capability string nested under a single
+// policy path entry, e.g. {"capabilities": ["read", "list"]}.
+func renderPathCapabilities(pathValue interface{}, vars policyTemplateVars, strict bool) (interface{}, error) {
+	capabilities, ok := pathValue.(map[string]interface{})
+	if !ok {
+		return pathValue, nil
+	}
+
+	rendered := make(map[string]interface{}, len(capabilities))
+	for key, value := range capabilities {
+		list, ok := value.([]interface{})
+		if !ok {
+			rendered[key] = value
+			continue
+		}
+
+		renderedList := make([]interface{}, len(list))
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				renderedList[i] = item
+				continue
+			}
+
+			expanded, err := expandTemplate(s, vars, strict)
+			if err != nil {
+				return nil, err
+			}
+			renderedList[i] = expanded
+		}
+		rendered[key] = renderedList
+	}
+
+	return rendered, nil
+}
+
+// expandTemplate parses and executes text as a Go template against vars.
+func expandTemplate(text string, vars policyTemplateVars, strict bool) (string, error) {
+	tmpl := template.New("policy")
+	if strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	tmpl, err := tmpl.Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
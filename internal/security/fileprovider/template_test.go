@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	vars := newPolicyTemplateVars("my-service", "edgex")
+
+	expanded, err := expandTemplate("secret/edgex/{{.ServiceName}}/*", vars, false)
+	require.NoError(t, err)
+	assert.Equal(t, "secret/edgex/my-service/*", expanded)
+}
+
+func TestExpandTemplate_StrictModeFailsOnUndefinedMapKey(t *testing.T) {
+	vars := policyTemplateVars{Env: map[string]string{"HOME": "/root"}}
+
+	_, err := expandTemplate("{{.Env.NOT_SET}}", vars, true)
+	assert.Error(t, err)
+}
+
+func TestExpandTemplate_NonStrictModeToleratesUndefinedMapKey(t *testing.T) {
+	vars := policyTemplateVars{Env: map[string]string{"HOME": "/root"}}
+
+	expanded, err := expandTemplate("{{.Env.NOT_SET}}", vars, false)
+	require.NoError(t, err)
+	assert.Equal(t, "<no value>", expanded)
+}
+
+func TestNewPolicyTemplateVars_UUIDAndRandomIDAreConsistent(t *testing.T) {
+	vars := newPolicyTemplateVars("my-service", "edgex")
+
+	assert.Equal(t, vars.UUID[:8], vars.RandomID)
+	assert.Len(t, vars.UUID, 36)
+}
+
+func TestRenderPolicyTemplate(t *testing.T) {
+	vars := newPolicyTemplateVars("my-service", "edgex")
+	policy := map[string]interface{}{
+		"path": map[string]interface{}{
+			"secret/edgex/{{.ServiceName}}/*": map[string]interface{}{
+				"capabilities": []interface{}{"read", "list"},
+			},
+		},
+	}
+
+	rendered, err := renderPolicyTemplate(policy, vars, false)
+	require.NoError(t, err)
+
+	renderedPaths, ok := rendered["path"].(map[string]interface{})
+	require.True(t, ok)
+	_, ok = renderedPaths["secret/edgex/my-service/*"]
+	assert.True(t, ok)
+}
+
+func TestRenderPolicyTemplate_NoPathKeyPassesThrough(t *testing.T) {
+	vars := newPolicyTemplateVars("my-service", "edgex")
+	policy := map[string]interface{}{"foo": "bar"}
+
+	rendered, err := renderPolicyTemplate(policy, vars, false)
+	require.NoError(t, err)
+	assert.Equal(t, policy, rendered)
+}
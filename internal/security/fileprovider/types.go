@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/edgexfoundry/edgex-go/internal/security/fileprovider/config"
+	secretstoreConfig "github.com/edgexfoundry/edgex-go/internal/security/secretstore/config"
+	"github.com/edgexfoundry/go-mod-secrets/v3/pkg/token/fileioperformer"
+)
+
+// TokenProvider bootstraps Vault tokens for every service listed in a token
+// configuration file, optionally staying resident to renew them.
+type TokenProvider interface {
+	SetConfiguration(secretStoreConfig secretstoreConfig.SecretStoreInfo, tokenConfig config.TokenFileProviderInfo)
+	SetRenderOnly(renderOnly bool)
+	Run() error
+	RunDaemon(ctx context.Context) error
+}
+
+// ServiceInfo is one service's entry in a TokenConfFile.
+type ServiceInfo struct {
+	UseDefaults     bool
+	CustomPolicy    map[string]interface{}
+	FilePermissions *config.FilePermissions
+	Sink            *config.SinkInfo
+
+	// AuthMethod selects how the service authenticates to Vault -- one of
+	// authMethodUserPass (the default), authMethodAppRole, or authMethodJWT.
+	AuthMethod string
+	// JWTAuth is required when AuthMethod is authMethodJWT.
+	JWTAuth *config.JWTAuthInfo
+}
+
+// TokenConfFile is the parsed shape of the token configuration file: one
+// ServiceInfo per service name.
+type TokenConfFile map[string]ServiceInfo
+
+// mergeWith layers other under tc: entries already in tc win over same-named
+// entries in other.
+func (tc TokenConfFile) mergeWith(other TokenConfFile) TokenConfFile {
+	merged := make(TokenConfFile, len(tc)+len(other))
+	for name, info := range other {
+		merged[name] = info
+	}
+	for name, info := range tc {
+		merged[name] = info
+	}
+	return merged
+}
+
+// addSecretstoreTokensEnvKey is the environment variable holding a JSON array of
+// extra service names that should get default-policy tokens without an entry in
+// the on-disk token configuration file.
+const addSecretstoreTokensEnvKey = "ADD_SECRETSTORE_TOKENS"
+
+// GetTokenConfigFromEnv parses addSecretstoreTokensEnvKey, if set, into a
+// TokenConfFile where every named service uses the default policy.
+func GetTokenConfigFromEnv() (TokenConfFile, error) {
+	raw, ok := os.LookupEnv(addSecretstoreTokensEnvKey)
+	if !ok || raw == "" {
+		return TokenConfFile{}, nil
+	}
+
+	var serviceNames []string
+	if err := json.Unmarshal([]byte(raw), &serviceNames); err != nil {
+		return nil, fmt.Errorf("failed to parse %s as a JSON array of service names: %w", addSecretstoreTokensEnvKey, err)
+	}
+
+	tokenConf := make(TokenConfFile, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		tokenConf[serviceName] = ServiceInfo{UseDefaults: true}
+	}
+
+	return tokenConf, nil
+}
+
+// LoadTokenConfig reads and JSON-decodes the token configuration file at path
+// into out.
+func LoadTokenConfig(fileOpener fileioperformer.FileIoPerformer, path string, out *TokenConfFile) error {
+	reader, err := fileOpener.OpenFileReader(path, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(reader).Decode(out)
+}
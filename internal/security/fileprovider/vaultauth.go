@@ -0,0 +1,196 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	secretstoreConfig "github.com/edgexfoundry/edgex-go/internal/security/secretstore/config"
+)
+
+// vaultAuthClient talks directly to Vault's HTTP API for the pieces of approle and
+// jwt auth-method provisioning/login that go-mod-secrets' secrets.SecretStoreClient
+// doesn't implement. It exists for the same reason the k8s-secret sink in sink.go
+// talks to the Kubernetes API server directly instead of depending on client-go:
+// the capability the service needs isn't exposed by the library this package
+// otherwise builds on.
+type vaultAuthClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// newVaultAuthClient builds a vaultAuthClient addressing the Vault instance
+// described by secretStoreConfig.
+func newVaultAuthClient(secretStoreConfig secretstoreConfig.SecretStoreInfo) *vaultAuthClient {
+	return &vaultAuthClient{
+		baseURL: fmt.Sprintf("%s://%s:%d", secretStoreConfig.Protocol, secretStoreConfig.Host, secretStoreConfig.Port),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultErrorResponse is the body Vault returns alongside a non-2xx status.
+type vaultErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// do issues a Vault API request, sending privilegedToken as the X-Vault-Token
+// header when non-empty, and decodes a 2xx response body into out.
+func (c *vaultAuthClient) do(method string, path string, privilegedToken string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if privilegedToken != "" {
+		req.Header.Set("X-Vault-Token", privilegedToken)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var vaultErr vaultErrorResponse
+		respBody, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(respBody, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault request %s %s failed with status %d: %s", method, path, resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("vault request %s %s failed with status %d: %s", method, path, resp.StatusCode, string(respBody))
+	}
+
+	if out == nil || resp.ContentLength == 0 {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// vaultDataResponse is the envelope Vault wraps read responses in.
+type vaultDataResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// createPolicy writes policyHCL as the ACL policy named name.
+func (c *vaultAuthClient) createPolicy(privilegedToken string, name string, policyHCL string) error {
+	return c.do(http.MethodPut, "/v1/sys/policies/acl/"+name, privilegedToken, map[string]string{"policy": policyHCL}, nil)
+}
+
+// createAppRole provisions (or updates) the approle role named roleName, binding
+// it to policyName with the given default token TTL.
+func (c *vaultAuthClient) createAppRole(privilegedToken string, roleName string, policyName string, tokenTTL string) error {
+	return c.do(http.MethodPost, "/v1/auth/approle/role/"+roleName, privilegedToken, map[string]interface{}{
+		"token_policies": []string{policyName},
+		"token_ttl":      tokenTTL,
+	}, nil)
+}
+
+// readAppRoleID returns roleName's role_id.
+func (c *vaultAuthClient) readAppRoleID(privilegedToken string, roleName string) (string, error) {
+	var response vaultDataResponse
+	if err := c.do(http.MethodGet, "/v1/auth/approle/role/"+roleName+"/role-id", privilegedToken, nil, &response); err != nil {
+		return "", err
+	}
+
+	roleID, ok := response.Data["role_id"].(string)
+	if !ok {
+		return "", fmt.Errorf("role-id response for %s did not contain a role_id", roleName)
+	}
+	return roleID, nil
+}
+
+// generateAppRoleSecretID mints a new secret_id for roleName.
+func (c *vaultAuthClient) generateAppRoleSecretID(privilegedToken string, roleName string) (string, error) {
+	var response vaultDataResponse
+	if err := c.do(http.MethodPost, "/v1/auth/approle/role/"+roleName+"/secret-id", privilegedToken, nil, &response); err != nil {
+		return "", err
+	}
+
+	secretID, ok := response.Data["secret_id"].(string)
+	if !ok {
+		return "", fmt.Errorf("secret-id response for %s did not contain a secret_id", roleName)
+	}
+	return secretID, nil
+}
+
+// appRoleLogin logs in via the approle auth method, returning the same
+// vaultTokenResponse shape as secrets.SecretStoreClient's userpass login.
+func (c *vaultAuthClient) appRoleLogin(roleID string, secretID string) (interface{}, error) {
+	var response vaultTokenResponse
+	if err := c.do(http.MethodPost, "/v1/auth/approle/login", "", map[string]string{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// createJWTRole registers roleName as a jwt auth role bound to policyName,
+// accepting tokens issued by issuer for audience.
+func (c *vaultAuthClient) createJWTRole(privilegedToken string, roleName string, policyName string, issuer string, audience string) error {
+	return c.do(http.MethodPost, "/v1/auth/jwt/role/"+roleName, privilegedToken, map[string]interface{}{
+		"role_type":       "jwt",
+		"bound_issuer":    issuer,
+		"bound_audiences": []string{audience},
+		"token_policies":  []string{policyName},
+		"user_claim":      "sub",
+	}, nil)
+}
+
+// policyToHCL renders policy's "path" entries as a Vault ACL policy document, the
+// format Vault's sys/policies/acl endpoint expects. Non-string/non-list entries
+// are skipped rather than rejected, consistent with renderPolicyTemplate treating
+// an unrecognized shape as pass-through data rather than an error.
+func policyToHCL(policy map[string]interface{}) string {
+	paths, _ := policy["path"].(map[string]interface{})
+
+	var hcl strings.Builder
+	for path, value := range paths {
+		capabilities, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		caps, _ := capabilities["capabilities"].([]interface{})
+
+		capStrings := make([]string, 0, len(caps))
+		for _, c := range caps {
+			if s, ok := c.(string); ok {
+				capStrings = append(capStrings, fmt.Sprintf("%q", s))
+			}
+		}
+
+		fmt.Fprintf(&hcl, "path %q {\n  capabilities = [%s]\n}\n", path, strings.Join(capStrings, ", "))
+	}
+
+	return hcl.String()
+}
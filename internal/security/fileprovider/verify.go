@@ -0,0 +1,261 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-secrets/v3/pkg/token/fileioperformer"
+)
+
+// detachedSignatureSuffix is appended to a token config file's path to find its
+// companion detached JWS signature, e.g. "token-config.json" -> "token-config.json.sig".
+const detachedSignatureSuffix = ".sig"
+
+// jwsAlgRS256 is the only JWS "alg" this package signs or verifies.
+const jwsAlgRS256 = "RS256"
+
+// jwsHeader is the JOSE header of a detached JWS produced by SignTokenConfigFile.
+type jwsHeader struct {
+	Alg string `json:"alg"`
+}
+
+// verifyConfigSignature checks configPath's detached JWS signature against every
+// public key found in trustedSignersDir, succeeding as soon as one key verifies a
+// signature computed over the file's current contents. It is used to refuse
+// minting tokens from a token config file that is unsigned or signed by an untrusted
+// key, so a compromised config-file writer can't silently add an over-privileged
+// service entry.
+func verifyConfigSignature(fileOpener fileioperformer.FileIoPerformer, configPath string, trustedSignersDir string) error {
+	payload, err := readFile(fileOpener, configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read token config file %s: %w", configPath, err)
+	}
+
+	sigPath := configPath + detachedSignatureSuffix
+	serialized, err := readFile(fileOpener, sigPath)
+	if err != nil {
+		return fmt.Errorf("token config is unsigned, no %s file found: %w", sigPath, err)
+	}
+
+	trustedKeys, err := loadTrustedPublicKeys(fileOpener, trustedSignersDir)
+	if err != nil {
+		return fmt.Errorf("failed to load trusted signer keys from %s: %w", trustedSignersDir, err)
+	}
+	if len(trustedKeys) == 0 {
+		return fmt.Errorf("no trusted signer keys found in %s", trustedSignersDir)
+	}
+
+	if err := verifyDetachedSignature(string(serialized), payload, trustedKeys); err != nil {
+		return fmt.Errorf("signature for %s did not verify against any trusted key in %s: %w", configPath, trustedSignersDir, err)
+	}
+
+	return nil
+}
+
+// verifyDetachedSignature parses serialized as a detached JWS compact
+// serialization and checks it against payload using whichever of trustedKeys is
+// an RSA key, succeeding as soon as one verifies.
+func verifyDetachedSignature(serialized string, payload []byte, trustedKeys []crypto.PublicKey) error {
+	headerB64, signature, err := parseDetachedJWS(serialized)
+	if err != nil {
+		return err
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(headerB64)
+	if err != nil {
+		return fmt.Errorf("failed to decode JWS header: %w", err)
+	}
+
+	var header jwsHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return fmt.Errorf("failed to parse JWS header: %w", err)
+	}
+	if header.Alg != jwsAlgRS256 {
+		return fmt.Errorf("unsupported JWS algorithm %q", header.Alg)
+	}
+
+	digest := sha256.Sum256(signingInput(headerB64, payload))
+	for _, key := range trustedKeys {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature did not verify against any trusted key")
+}
+
+// parseDetachedJWS splits serialized into its base64url header and signature,
+// rejecting anything that isn't the "header..signature" shape of a detached JWS
+// compact serialization (RFC 7515 Appendix F) -- the middle payload segment must
+// be empty, since the payload is never embedded in the file.
+func parseDetachedJWS(serialized string) (headerB64 string, signature []byte, err error) {
+	parts := strings.Split(strings.TrimSpace(serialized), ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return "", nil, fmt.Errorf("not a detached JWS compact serialization")
+	}
+
+	signature, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode JWS signature: %w", err)
+	}
+
+	return parts[0], signature, nil
+}
+
+// signingInput builds the RFC 7515 JWS Signing Input for a detached payload:
+// BASE64URL(header) || '.' || BASE64URL(payload). The payload is never written
+// out alongside it, so both the signer and every verifier must reconstruct this
+// same input from a payload they read independently.
+func signingInput(headerB64 string, payload []byte) []byte {
+	return []byte(headerB64 + "." + base64.RawURLEncoding.EncodeToString(payload))
+}
+
+// loadTrustedPublicKeys reads every PEM-encoded public key file in dir. Listing
+// dir's entries still goes through os.ReadDir because fileioperformer.FileIoPerformer
+// has no directory-listing method; each key file's contents are read through
+// fileOpener like the rest of this package's I/O.
+func loadTrustedPublicKeys(fileOpener fileioperformer.FileIoPerformer, dir string) ([]crypto.PublicKey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []crypto.PublicKey
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		keyBytes, err := readFile(fileOpener, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trusted signer key %s: %w", entry.Name(), err)
+		}
+
+		block, _ := pem.Decode(keyBytes)
+		if block == nil {
+			continue
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// readFile reads path fully using fileOpener, consistent with how the rest of this
+// package performs file I/O rather than calling os directly.
+func readFile(fileOpener fileioperformer.FileIoPerformer, path string) ([]byte, error) {
+	reader, err := fileOpener.OpenFileReader(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+// SignTokenConfigFile produces a detached JWS signature for configPath using the
+// PEM/PKCS8-encoded RSA private key at keyPath and writes it to configPath+".sig".
+// The payload is never embedded in the signature file -- only the header and
+// signature segments are written, separated by an empty middle segment -- so
+// verifyConfigSignature must reconstruct the signing input from the config file
+// it reads independently. It backs the `secrets-config sign-token-config` CLI
+// subcommand operators run offline to produce a signature before distributing a
+// token config file.
+func SignTokenConfigFile(fileOpener fileioperformer.FileIoPerformer, configPath string, keyPath string) error {
+	payload, err := readFile(fileOpener, configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read token config file %s: %w", configPath, err)
+	}
+
+	keyBytes, err := readFile(fileOpener, keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signing key %s: %w", keyPath, err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return fmt.Errorf("no PEM block found in signing key %s", keyPath)
+	}
+
+	privateKeyAny, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse signing key %s: %w", keyPath, err)
+	}
+
+	privateKey, ok := privateKeyAny.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("signing key %s is not an RSA key", keyPath)
+	}
+
+	serialized, err := signDetached(payload, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign %s: %w", configPath, err)
+	}
+
+	sigPath := configPath + detachedSignatureSuffix
+	writeCloser, err := fileOpener.OpenFileWriter(sigPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return fmt.Errorf("failed to open signature file %s: %w", sigPath, err)
+	}
+
+	if _, err := io.WriteString(writeCloser, serialized); err != nil {
+		_ = writeCloser.Close()
+		return fmt.Errorf("failed to write signature file %s: %w", sigPath, err)
+	}
+
+	return writeCloser.Close()
+}
+
+// signDetached computes the detached JWS compact serialization of payload under
+// privateKey: "BASE64URL(header)..BASE64URL(signature)", with the payload segment
+// left empty.
+func signDetached(payload []byte, privateKey *rsa.PrivateKey) (string, error) {
+	headerBytes, err := json.Marshal(jwsHeader{Alg: jwsAlgRS256})
+	if err != nil {
+		return "", err
+	}
+	headerB64 := base64.RawURLEncoding.EncodeToString(headerBytes)
+
+	digest := sha256.Sum256(signingInput(headerB64, payload))
+	signature, err := rsa.SignPKCS1v15(nil, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return headerB64 + ".." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fileprovider
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func TestSignAndVerifyDetached_RoundTrip(t *testing.T) {
+	key := testRSAKey(t)
+	payload := []byte(`{"service-a":{"useDefaults":true}}`)
+
+	serialized, err := signDetached(payload, key)
+	require.NoError(t, err)
+
+	err = verifyDetachedSignature(serialized, payload, []crypto.PublicKey{&key.PublicKey})
+	assert.NoError(t, err)
+}
+
+func TestVerifyDetachedSignature_PayloadDoesNotAppearInSignature(t *testing.T) {
+	key := testRSAKey(t)
+	payload := []byte("super-secret-config-contents")
+
+	serialized, err := signDetached(payload, key)
+	require.NoError(t, err)
+
+	assert.NotContains(t, serialized, "c3VwZXItc2VjcmV0")
+}
+
+func TestVerifyDetachedSignature_WrongPayloadFails(t *testing.T) {
+	key := testRSAKey(t)
+	serialized, err := signDetached([]byte("original"), key)
+	require.NoError(t, err)
+
+	err = verifyDetachedSignature(serialized, []byte("tampered"), []crypto.PublicKey{&key.PublicKey})
+	assert.Error(t, err)
+}
+
+func TestVerifyDetachedSignature_UntrustedKeyFails(t *testing.T) {
+	signingKey := testRSAKey(t)
+	otherKey := testRSAKey(t)
+	payload := []byte("payload")
+
+	serialized, err := signDetached(payload, signingKey)
+	require.NoError(t, err)
+
+	err = verifyDetachedSignature(serialized, payload, []crypto.PublicKey{&otherKey.PublicKey})
+	assert.Error(t, err)
+}
+
+func TestParseDetachedJWS(t *testing.T) {
+	tests := []struct {
+		name       string
+		serialized string
+		wantErr    bool
+	}{
+		{name: "valid detached", serialized: "aGVhZGVy..c2ln", wantErr: false},
+		{name: "embedded payload rejected", serialized: "aGVhZGVy.cGF5bG9hZA.c2ln", wantErr: true},
+		{name: "too few segments", serialized: "aGVhZGVy.c2ln", wantErr: true},
+		{name: "not base64", serialized: "aGVhZGVy..not base64!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseDetachedJWS(tt.serialized)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
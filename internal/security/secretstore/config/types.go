@@ -0,0 +1,33 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License. You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License
+// is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+// or implied. See the License for the specific language governing permissions and limitations under
+// the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package config
+
+// SecretStoreInfo configures how the file token provider and related tooling
+// reach the secret store.
+type SecretStoreInfo struct {
+	// Protocol, Host and Port address the Vault API directly, for auth methods
+	// go-mod-secrets' SecretStoreClient doesn't implement (approle, jwt).
+	Protocol string
+	Host     string
+	Port     int
+
+	// RequireSignedConfig refuses to mint tokens unless the token configuration
+	// file carries a detached signature verified against TrustedSignersDir.
+	RequireSignedConfig bool
+	// Namespace is the secret store namespace service policies are scoped under.
+	Namespace string
+}